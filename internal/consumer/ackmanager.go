@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"sync"
+
+	"github.com/uber-go/kafka-client/internal/list"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type (
+	// ackID identifies an in-flight, unacknowledged offset tracked by an ackManager.
+	ackID struct {
+		node   *list.Node
+		offset int64
+	}
+
+	// ackManager tracks the offsets currently in flight for a partition and
+	// computes the highest offset that can safely be committed - the
+	// largest offset such that it and everything before it has been
+	// acked or nacked.
+	ackManager struct {
+		mu          sync.Mutex
+		outstanding *list.List
+		commitLevel int64
+		tally       tally.Scope
+		logger      *zap.Logger
+	}
+
+	offsetState struct {
+		offset int64
+		acked  bool
+	}
+)
+
+// offsetStatePool recycles offsetStates across ackManagers, mirroring the
+// Node pooling in the list package - GetAckID/advanceLocked run once per
+// message and should not allocate on the steady-state path.
+var offsetStatePool = sync.Pool{New: func() interface{} { return &offsetState{} }}
+
+// newAckManager returns an ackManager that can track up to maxOutstanding
+// in-flight offsets at a time.
+func newAckManager(maxOutstanding int, scope tally.Scope, logger *zap.Logger) *ackManager {
+	return &ackManager{
+		outstanding: list.New(maxOutstanding),
+		commitLevel: -1,
+		tally:       scope,
+		logger:      logger,
+	}
+}
+
+// GetAckID starts tracking offset, returning list.ErrCapacity if
+// maxOutstanding offsets are already in flight.
+func (m *ackManager) GetAckID(offset int64) (ackID, error) {
+	st := offsetStatePool.Get().(*offsetState)
+	st.offset = offset
+	st.acked = false
+	node, err := m.outstanding.PushBack(st)
+	if err != nil {
+		offsetStatePool.Put(st)
+		return ackID{}, err
+	}
+	return ackID{node: node, offset: offset}, nil
+}
+
+// Ack marks id as successfully processed.
+func (m *ackManager) Ack(id ackID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id.node.Value.(*offsetState).acked = true
+	m.advanceLocked()
+}
+
+// Nack marks id as handled - e.g. routed to a DLQ - without successful
+// processing. Like Ack, it unblocks the commit level; the message itself
+// will not be retried by this consumer.
+func (m *ackManager) Nack(id ackID) {
+	m.Ack(id)
+}
+
+// advanceLocked moves the commit level past every contiguous acked offset
+// at the front of the outstanding list.
+func (m *ackManager) advanceLocked() {
+	for {
+		front := m.outstanding.Front()
+		if front == nil {
+			break
+		}
+		st := front.Value.(*offsetState)
+		if !st.acked {
+			break
+		}
+		m.commitLevel = st.offset
+		m.outstanding.Remove(front)
+		offsetStatePool.Put(st)
+	}
+}
+
+// CommitLevel returns the highest offset that is safe to commit, or -1 if
+// nothing has been acked yet.
+func (m *ackManager) CommitLevel() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commitLevel
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"math/rand"
+	"time"
+)
+
+type (
+	// SimpleBackoff computes exponentially increasing retry delays with
+	// jitter: interval = min(InitialInterval * Multiplier^attempt, MaxInterval),
+	// randomized by +/- Jitter (a fraction) of that interval.
+	SimpleBackoff struct {
+		// InitialInterval is the delay before the first retry
+		InitialInterval time.Duration
+		// Multiplier scales the interval after each attempt
+		Multiplier float64
+		// MaxInterval caps the computed interval
+		MaxInterval time.Duration
+		// Jitter is the fraction (0,1] of the computed interval to randomize by
+		Jitter float64
+	}
+)
+
+// DefaultReconnectBackoff is used when Options.ReconnectBackoff is unset.
+var DefaultReconnectBackoff = &SimpleBackoff{
+	InitialInterval: 250 * time.Millisecond,
+	Multiplier:      2.0,
+	MaxInterval:     30 * time.Second,
+	Jitter:          0.2,
+}
+
+// Duration returns the delay to wait before retry number attempt (0-based).
+func (b *SimpleBackoff) Duration(attempt int) time.Duration {
+	if b == nil {
+		b = DefaultReconnectBackoff
+	}
+	interval := float64(b.InitialInterval)
+	for i := 0; i < attempt; i++ {
+		interval *= b.Multiplier
+		if interval >= float64(b.MaxInterval) {
+			interval = float64(b.MaxInterval)
+			break
+		}
+	}
+	if b.Jitter > 0 {
+		delta := interval * b.Jitter
+		interval = interval - delta + rand.Float64()*2*delta
+	}
+	if interval < 0 {
+		interval = 0
+	}
+	return time.Duration(interval)
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimpleBackoffCapsAtMaxInterval(t *testing.T) {
+	b := &SimpleBackoff{
+		InitialInterval: time.Millisecond,
+		Multiplier:      2.0,
+		MaxInterval:     10 * time.Millisecond,
+		Jitter:          0,
+	}
+	d := b.Duration(10)
+	if d != 10*time.Millisecond {
+		t.Fatalf("expected duration to cap at MaxInterval, got %v", d)
+	}
+}
+
+func TestSimpleBackoffGrowsWithAttempt(t *testing.T) {
+	b := &SimpleBackoff{
+		InitialInterval: time.Millisecond,
+		Multiplier:      2.0,
+		MaxInterval:     time.Second,
+		Jitter:          0,
+	}
+	if b.Duration(0) != time.Millisecond {
+		t.Fatalf("expected first attempt to use InitialInterval, got %v", b.Duration(0))
+	}
+	if b.Duration(1) != 2*time.Millisecond {
+		t.Fatalf("expected second attempt to double, got %v", b.Duration(1))
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import "github.com/Shopify/sarama"
+
+type (
+	// DLQ is implemented by anything that can take delivery of a message
+	// that a consumer has given up on.
+	DLQ interface {
+		// SendMessage hands a single message to the dead letter topic.
+		// Depending on the implementation it either blocks until the
+		// broker acknowledges it, or enqueues it for a later flush and
+		// returns without waiting on the network (as batchDLQ does).
+		SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error)
+		// SendMessages sends a batch of messages to the dead letter topic.
+		SendMessages(msgs []*sarama.ProducerMessage) error
+		Close() error
+	}
+)
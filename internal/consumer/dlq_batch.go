@@ -0,0 +1,185 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/uber-go/kafka-client/internal/metrics"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type (
+	// batchDLQ accumulates failed messages and flushes them to an
+	// underlying DLQ via SendMessages, instead of blocking the caller on
+	// a per-message network round trip. A batch is flushed once it
+	// reaches DLQBatchSize, or DLQFlushInterval elapses, whichever comes
+	// first.
+	//
+	// Not yet constructed from a kafka.Consumer - wiring Options'
+	// DLQBatchSize/DLQFlushInterval/DLQMaxRetries through to a
+	// newBatchDLQ call is deferred to the chunk that assembles the
+	// top-level Consumer implementation.
+	batchDLQ struct {
+		underlying    DLQ
+		batchSize     int
+		flushInterval time.Duration
+		maxRetries    int
+		tally         tally.Scope
+		logger        *zap.Logger
+		msgC          chan *dlqEntry
+		stopC         chan struct{}
+		doneC         chan struct{}
+	}
+
+	dlqEntry struct {
+		msg     *sarama.ProducerMessage
+		retries int
+	}
+)
+
+// newBatchDLQ returns a DLQ that batches messages destined for underlying.
+func newBatchDLQ(underlying DLQ, options *Options, scope tally.Scope, logger *zap.Logger) *batchDLQ {
+	d := &batchDLQ{
+		underlying:    underlying,
+		batchSize:     options.DLQBatchSize,
+		flushInterval: options.DLQFlushInterval,
+		maxRetries:    options.DLQMaxRetries,
+		tally:         scope,
+		logger:        logger,
+		msgC:          make(chan *dlqEntry, options.DLQBatchSize*2),
+		stopC:         make(chan struct{}),
+		doneC:         make(chan struct{}),
+	}
+	go d.flushLoop()
+	return d
+}
+
+// SendMessage enqueues msg for the next batch flush. Unlike a direct
+// SyncProducer.SendMessage call, this never blocks on the network.
+func (d *batchDLQ) SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error) {
+	select {
+	case d.msgC <- &dlqEntry{msg: msg}:
+		return 0, 0, nil
+	case <-d.stopC:
+		return 0, 0, fmt.Errorf("batchDLQ: closed")
+	}
+}
+
+// SendMessages enqueues every message in msgs for the next batch flush.
+func (d *batchDLQ) SendMessages(msgs []*sarama.ProducerMessage) error {
+	for _, msg := range msgs {
+		if _, _, err := d.SendMessage(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes any buffered messages and closes the underlying DLQ.
+func (d *batchDLQ) Close() error {
+	close(d.stopC)
+	<-d.doneC
+	return d.underlying.Close()
+}
+
+func (d *batchDLQ) flushLoop() {
+	defer close(d.doneC)
+	ticker := time.NewTicker(d.flushInterval)
+	defer ticker.Stop()
+	batch := make([]*dlqEntry, 0, d.batchSize)
+	for {
+		select {
+		case e := <-d.msgC:
+			batch = append(batch, e)
+			if len(batch) >= d.batchSize {
+				batch = d.flush(batch)
+			}
+		case <-ticker.C:
+			batch = d.flush(batch)
+		case <-d.stopC:
+			for {
+				select {
+				case e := <-d.msgC:
+					batch = append(batch, e)
+				default:
+					d.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush sends batch to the underlying DLQ and returns the (possibly
+// non-empty) subset that must be retried on the next flush.
+func (d *batchDLQ) flush(batch []*dlqEntry) []*dlqEntry {
+	if len(batch) == 0 {
+		return batch
+	}
+	msgs := make([]*sarama.ProducerMessage, len(batch))
+	for i, e := range batch {
+		msgs[i] = e.msg
+	}
+	err := d.underlying.SendMessages(msgs)
+	if err == nil {
+		d.tally.Counter(metrics.KafkaDLQBatchFlushed).Inc(int64(len(batch)))
+		return batch[:0]
+	}
+
+	// sarama.ProducerErrors carries exactly the subset of messages that
+	// failed - only those are retried, everything else in the batch
+	// succeeded and is dropped from further tracking.
+	perrs, ok := err.(sarama.ProducerErrors)
+	if !ok {
+		d.logger.Error("dlq batch flush failed", zap.Error(err))
+		return batch[:0]
+	}
+
+	d.tally.Counter(metrics.KafkaDLQBatchPartialFailure).Inc(1)
+	retry := make([]*dlqEntry, 0, len(perrs))
+	for _, perr := range perrs {
+		e := findEntry(batch, perr.Msg)
+		if e == nil {
+			continue
+		}
+		e.retries++
+		if e.retries > d.maxRetries {
+			d.tally.Counter(metrics.KafkaDLQMessageDropped).Inc(1)
+			d.logger.Error("dropping dlq message after exceeding max retries", zap.Error(perr.Err))
+			continue
+		}
+		retry = append(retry, e)
+	}
+	return retry
+}
+
+func findEntry(batch []*dlqEntry, msg *sarama.ProducerMessage) *dlqEntry {
+	for _, e := range batch {
+		if e.msg == msg {
+			return e
+		}
+	}
+	return nil
+}
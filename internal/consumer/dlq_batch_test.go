@@ -0,0 +1,127 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+// TestBatchDLQRetriesOnlyFailedSubset seeds one key as already succeeded so
+// that a single flush - triggered by the batch filling up, not by the
+// ticker - contains a genuine mix of one immediate success and two
+// failures, then confirms only the failed pair is retried on the next
+// flush.
+func TestBatchDLQRetriesOnlyFailedSubset(t *testing.T) {
+	underlying := newMockDLQProducer()
+	underlying.seedSucceeded("dup-key")
+
+	options := DefaultOptions()
+	options.DLQBatchSize = 3
+	options.DLQFlushInterval = 150 * time.Millisecond
+
+	d := newBatchDLQ(underlying, options, tally.NoopScope, zap.NewNop())
+	defer d.Close()
+
+	for _, key := range []string{"dup-key", "new-1", "new-2"} {
+		msg := &sarama.ProducerMessage{
+			Topic: "dlq",
+			Key:   sarama.StringEncoder(key),
+			Value: sarama.ByteEncoder([]byte("payload")),
+		}
+		if _, _, err := d.SendMessage(msg); err != nil {
+			t.Fatalf("SendMessage returned error: %v", err)
+		}
+	}
+
+	// The batch fills up and flushes immediately, well before the ticker
+	// would fire - only the pre-seeded key should have gone through.
+	deadline := time.After(100 * time.Millisecond)
+	for underlying.backlog() < 2 {
+		select {
+		case <-time.After(5 * time.Millisecond):
+		case <-deadline:
+			t.Fatalf("expected the pre-seeded key to succeed on the first flush, backlog=%d", underlying.backlog())
+		}
+	}
+	if backlog := underlying.backlog(); backlog != 2 {
+		t.Fatalf("expected exactly one message to succeed on the first flush (plus the seed), backlog=%d", backlog)
+	}
+
+	// The next flush, driven by the ticker, retries new-1 and new-2 - both
+	// are now known keys, so they succeed.
+	deadline = time.After(time.Second)
+	for underlying.backlog() < 4 {
+		select {
+		case <-time.After(5 * time.Millisecond):
+		case <-deadline:
+			t.Fatalf("expected the retried pair to eventually succeed, backlog=%d", underlying.backlog())
+		}
+	}
+}
+
+// TestBatchDLQDropsMessageAfterExceedingMaxRetries confirms a message whose
+// every delivery attempt fails stops being retried once it exceeds
+// DLQMaxRetries, instead of being retried forever.
+func TestBatchDLQDropsMessageAfterExceedingMaxRetries(t *testing.T) {
+	underlying := newMockDLQProducer()
+	underlying.poisonKey("poison")
+
+	options := DefaultOptions()
+	options.DLQBatchSize = 1
+	options.DLQFlushInterval = 10 * time.Millisecond
+	options.DLQMaxRetries = 1
+
+	d := newBatchDLQ(underlying, options, tally.NoopScope, zap.NewNop())
+	defer d.Close()
+
+	msg := &sarama.ProducerMessage{
+		Topic: "dlq",
+		Key:   sarama.StringEncoder("poison"),
+		Value: sarama.ByteEncoder([]byte("payload")),
+	}
+	if _, _, err := d.SendMessage(msg); err != nil {
+		t.Fatalf("SendMessage returned error: %v", err)
+	}
+
+	// One initial attempt plus one retry exceeds DLQMaxRetries and the
+	// message is dropped - it should never be sent a third time.
+	wantAttempts := options.DLQMaxRetries + 1
+	deadline := time.After(time.Second)
+	for underlying.attemptsFor("poison") < wantAttempts {
+		select {
+		case <-time.After(5 * time.Millisecond):
+		case <-deadline:
+			t.Fatalf("expected %d attempts before the message is dropped, got %d", wantAttempts, underlying.attemptsFor("poison"))
+		}
+	}
+
+	// Give the flush loop plenty of further ticks - a bug that retries
+	// past DLQMaxRetries would grow this count.
+	time.Sleep(10 * options.DLQFlushInterval)
+	if attempts := underlying.attemptsFor("poison"); attempts != wantAttempts {
+		t.Fatalf("expected dropped message to stop being retried at %d attempts, got %d", wantAttempts, attempts)
+	}
+}
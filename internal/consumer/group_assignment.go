@@ -0,0 +1,120 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"github.com/uber-go/kafka-client/internal/metrics"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type (
+	// groupAssignmentMonitor watches consumer group rebalance notifications
+	// and validates the resulting assignment against a RebalanceStrategy,
+	// surfacing violations (e.g. a Copartitioned group whose topics no
+	// longer share a partition count) through an error channel rather than
+	// failing silently.
+	//
+	// Not yet constructed from a kafka.Consumer - wiring Options'
+	// RebalanceStrategy through to a newGroupAssignmentMonitor call is
+	// deferred to the chunk that assembles the top-level Consumer
+	// implementation.
+	groupAssignmentMonitor struct {
+		sarama   SaramaConsumer
+		strategy RebalanceStrategy
+		errC     chan error
+		tally    tally.Scope
+		logger   *zap.Logger
+		stopC    chan struct{}
+	}
+)
+
+func newGroupAssignmentMonitor(sarama SaramaConsumer, strategy RebalanceStrategy, scope tally.Scope, logger *zap.Logger) *groupAssignmentMonitor {
+	if strategy == nil {
+		strategy = Range
+	}
+	return &groupAssignmentMonitor{
+		sarama:   sarama,
+		strategy: strategy,
+		errC:     make(chan error, 1),
+		tally:    scope,
+		logger:   logger,
+		stopC:    make(chan struct{}),
+	}
+}
+
+// Errors returns the channel that rebalance-strategy validation failures
+// are published on. Consumers of this channel should treat it the same
+// way they treat SaramaConsumer.Errors().
+func (m *groupAssignmentMonitor) Errors() <-chan error {
+	return m.errC
+}
+
+// Start begins watching for rebalance notifications.
+func (m *groupAssignmentMonitor) Start() {
+	go m.notificationLoop()
+}
+
+// Stop stops watching for rebalance notifications.
+func (m *groupAssignmentMonitor) Stop() {
+	close(m.stopC)
+}
+
+func (m *groupAssignmentMonitor) notificationLoop() {
+	for {
+		select {
+		case n, ok := <-m.sarama.Notifications():
+			if !ok {
+				return
+			}
+			m.validate(n.Current)
+		case <-m.stopC:
+			return
+		}
+	}
+}
+
+// validate looks up each subscribed topic's true partition count on the
+// cluster, rather than trusting len(current[topic]) - current is this
+// group member's own assigned share of each topic, which can coincidentally
+// match across topics with genuinely different total partition counts
+// (e.g. a Range-strategy member holding 3 of 10 partitions on one topic
+// and 3 of 9 on another), silently defeating Copartitioned.Validate.
+func (m *groupAssignmentMonitor) validate(current map[string][]int32) {
+	topicPartitionCounts := make(map[string]int32, len(current))
+	for topic := range current {
+		partitions, err := m.sarama.TopicPartitions(topic)
+		if err != nil {
+			m.logger.Error("failed to fetch topic partition count for rebalance validation",
+				zap.String("topic", topic), zap.Error(err))
+			return
+		}
+		topicPartitionCounts[topic] = int32(len(partitions))
+	}
+	if err := m.strategy.Validate(topicPartitionCounts); err != nil {
+		m.tally.Counter(metrics.KafkaRebalanceCopartitionMismatch).Inc(1)
+		m.logger.Error("rebalance strategy validation failed", zap.Error(err))
+		select {
+		case m.errC <- err:
+		default:
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+type (
+	// message is the concrete kafka.Message handed to application code. It
+	// is pooled per-partition by partitionConsumer - Ack/Nack return it to
+	// pool once the application is done with it, so steady-state delivery
+	// does not allocate a new wrapper per message.
+	message struct {
+		scm      *sarama.ConsumerMessage
+		ackID    ackID
+		ackMgr   *ackManager
+		dlq      DLQ
+		pool     *sync.Pool
+		zeroCopy bool
+		keyBuf   []byte
+		valBuf   []byte
+	}
+)
+
+// newMessage checks out a message wrapper from pool and binds it to scm,
+// id, ackMgr and dlq. If zeroCopy is false (the default), Key/Value are
+// copied into freshly allocated slices; this is what makes it safe to
+// return the wrapper to pool and reuse it for a later message without
+// corrupting data the application may still be holding onto. If
+// zeroCopy is true, Key/Value alias scm directly and the caller must not
+// retain them past Ack()/Nack() - sarama's consumer can release the
+// underlying read buffer once a message is considered processed.
+func newMessage(pool *sync.Pool, scm *sarama.ConsumerMessage, id ackID, ackMgr *ackManager, dlq DLQ, zeroCopy bool) *message {
+	m := pool.Get().(*message)
+	m.scm = scm
+	m.ackID = id
+	m.ackMgr = ackMgr
+	m.dlq = dlq
+	m.pool = pool
+	m.zeroCopy = zeroCopy
+	if !zeroCopy {
+		m.keyBuf = append([]byte(nil), scm.Key...)
+		m.valBuf = append([]byte(nil), scm.Value...)
+	}
+	return m
+}
+
+func (m *message) Key() []byte {
+	if m.zeroCopy {
+		return m.scm.Key
+	}
+	return m.keyBuf
+}
+
+func (m *message) Value() []byte {
+	if m.zeroCopy {
+		return m.scm.Value
+	}
+	return m.valBuf
+}
+
+func (m *message) Topic() string    { return m.scm.Topic }
+func (m *message) Partition() int32 { return m.scm.Partition }
+func (m *message) Offset() int64    { return m.scm.Offset }
+
+// Ack marks the message as successfully processed and returns the wrapper
+// to its pool.
+func (m *message) Ack() {
+	m.ackMgr.Ack(m.ackID)
+	m.release()
+}
+
+// Nack routes the message to the configured DLQ (if any) and unblocks the
+// commit level, then returns the wrapper to its pool. DLQ enqueueing does
+// not block on the network - the DLQ implementation is responsible for
+// buffering and flushing asynchronously, potentially well past the point
+// Nack returns and this wrapper is recycled. toProducerMessage therefore
+// always makes its own copy of Key/Value regardless of ZeroCopy - the
+// ZeroCopy "don't retain past Ack/Nack" contract is about what the
+// application may hold onto, not what the DLQ internally buffers.
+func (m *message) Nack() {
+	if m.dlq != nil {
+		if _, _, err := m.dlq.SendMessage(m.toProducerMessage()); err != nil {
+			m.ackMgr.logger.Error("failed to enqueue message to DLQ", zap.Error(err))
+		}
+	}
+	m.ackMgr.Nack(m.ackID)
+	m.release()
+}
+
+// release clears the wrapper's references to the message it carried and
+// returns it to pool. It must run after every field used by the caller has
+// been read, since pool may hand this wrapper to another goroutine as soon
+// as it is returned.
+func (m *message) release() {
+	pool := m.pool
+	m.scm, m.ackID, m.ackMgr, m.dlq, m.pool = nil, ackID{}, nil, nil, nil
+	pool.Put(m)
+}
+
+func (m *message) toProducerMessage() *sarama.ProducerMessage {
+	key := make([]byte, len(m.scm.Key))
+	copy(key, m.scm.Key)
+	value := make([]byte, len(m.scm.Value))
+	copy(value, m.scm.Value)
+	return &sarama.ProducerMessage{
+		Topic: m.scm.Topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+func benchmarkMessagePool(b *testing.B, zeroCopy bool) {
+	pool := &sync.Pool{New: func() interface{} { return &message{} }}
+	ackMgr := newAckManager(b.N+1, tally.NoopScope, zap.NewNop())
+	scm := &sarama.ConsumerMessage{
+		Topic:     "bench",
+		Partition: 0,
+		Key:       []byte("some-key"),
+		Value:     []byte("some-value-payload"),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id, err := ackMgr.GetAckID(int64(i))
+		if err != nil {
+			b.Fatalf("GetAckID returned error: %v", err)
+		}
+		msg := newMessage(pool, scm, id, ackMgr, nil, zeroCopy)
+		_ = msg.Key()
+		_ = msg.Value()
+		msg.Ack()
+	}
+}
+
+// BenchmarkMessagePoolCopy measures the steady-state allocation cost of
+// delivering a message with defensive Key/Value copies (the default).
+func BenchmarkMessagePoolCopy(b *testing.B) {
+	benchmarkMessagePool(b, false)
+}
+
+// BenchmarkMessagePoolZeroCopy measures the same path with ZeroCopy
+// enabled, which skips the Key/Value copy entirely.
+func BenchmarkMessagePoolZeroCopy(b *testing.B) {
+	benchmarkMessagePool(b, true)
+}
+
+// BenchmarkAckManagerGetAckID measures the allocation cost of tracking and
+// immediately acking an offset - both the list.Node and the offsetState
+// backing it are expected to come from their respective pools after the
+// first b.N iterations prime them.
+func BenchmarkAckManagerGetAckID(b *testing.B) {
+	ackMgr := newAckManager(1, tally.NoopScope, zap.NewNop())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id, err := ackMgr.GetAckID(int64(i))
+		if err != nil {
+			b.Fatalf("GetAckID returned error: %v", err)
+		}
+		ackMgr.Ack(id)
+	}
+}
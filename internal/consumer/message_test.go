@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+func TestMessageCopyModeSurvivesScmMutation(t *testing.T) {
+	pool := &sync.Pool{New: func() interface{} { return &message{} }}
+	ackMgr := newAckManager(10, tally.NoopScope, zap.NewNop())
+	id, err := ackMgr.GetAckID(0)
+	if err != nil {
+		t.Fatalf("GetAckID returned error: %v", err)
+	}
+
+	scm := &sarama.ConsumerMessage{Key: []byte("key"), Value: []byte("value")}
+	msg := newMessage(pool, scm, id, ackMgr, nil, false /* zeroCopy */)
+
+	scm.Key[0] = 'X'
+	scm.Value[0] = 'X'
+
+	if !bytes.Equal(msg.Key(), []byte("key")) {
+		t.Fatalf("expected copy-mode Key to be unaffected by scm mutation, got %q", msg.Key())
+	}
+	if !bytes.Equal(msg.Value(), []byte("value")) {
+		t.Fatalf("expected copy-mode Value to be unaffected by scm mutation, got %q", msg.Value())
+	}
+}
+
+func TestMessageZeroCopyAliasesScm(t *testing.T) {
+	pool := &sync.Pool{New: func() interface{} { return &message{} }}
+	ackMgr := newAckManager(10, tally.NoopScope, zap.NewNop())
+	id, err := ackMgr.GetAckID(0)
+	if err != nil {
+		t.Fatalf("GetAckID returned error: %v", err)
+	}
+
+	scm := &sarama.ConsumerMessage{Key: []byte("key"), Value: []byte("value")}
+	msg := newMessage(pool, scm, id, ackMgr, nil, true /* zeroCopy */)
+
+	scm.Value[0] = 'X'
+	if !bytes.Equal(msg.Value(), []byte("Xalue")) {
+		t.Fatalf("expected zero-copy Value to alias scm, got %q", msg.Value())
+	}
+}
+
+func TestMessageNackCopiesIntoDLQMessageRegardlessOfZeroCopy(t *testing.T) {
+	for _, zeroCopy := range []bool{false, true} {
+		pool := &sync.Pool{New: func() interface{} { return &message{} }}
+		ackMgr := newAckManager(10, tally.NoopScope, zap.NewNop())
+		id, err := ackMgr.GetAckID(0)
+		if err != nil {
+			t.Fatalf("GetAckID returned error: %v", err)
+		}
+
+		dlq := newMockDLQProducer()
+		scm := &sarama.ConsumerMessage{
+			Key:   append([]byte(nil), "key"...),
+			Value: append([]byte(nil), "value"...),
+		}
+		msg := newMessage(pool, scm, id, ackMgr, dlq, zeroCopy)
+		msg.Nack()
+
+		// A real DLQ (e.g. batchDLQ) can still be holding the
+		// *sarama.ProducerMessage built by Nack well after it returns.
+		// Mutating scm here - or reusing the wrapper/its buffers, which
+		// Nack's release() makes possible - must not be visible through
+		// whatever the DLQ is holding.
+		scm.Key[0] = 'X'
+		scm.Value[0] = 'X'
+
+		sent := dlq.lastSent()
+		if sent == nil {
+			t.Fatalf("zeroCopy=%v: expected a message to reach the DLQ", zeroCopy)
+		}
+		if got := string(sent.Key.(sarama.StringEncoder)); got != "key" {
+			t.Fatalf("zeroCopy=%v: expected DLQ message key to be unaffected by scm mutation, got %q", zeroCopy, got)
+		}
+		if got := string(sent.Value.(sarama.ByteEncoder)); got != "value" {
+			t.Fatalf("zeroCopy=%v: expected DLQ message value to be unaffected by scm mutation, got %q", zeroCopy, got)
+		}
+	}
+}
+
+func TestMessagePoolRecyclesWrapper(t *testing.T) {
+	pool := &sync.Pool{New: func() interface{} { return &message{} }}
+	ackMgr := newAckManager(10, tally.NoopScope, zap.NewNop())
+
+	id1, err := ackMgr.GetAckID(0)
+	if err != nil {
+		t.Fatalf("GetAckID returned error: %v", err)
+	}
+	first := newMessage(pool, &sarama.ConsumerMessage{Key: []byte("a")}, id1, ackMgr, nil, false)
+	first.Ack()
+
+	id2, err := ackMgr.GetAckID(1)
+	if err != nil {
+		t.Fatalf("GetAckID returned error: %v", err)
+	}
+	second := newMessage(pool, &sarama.ConsumerMessage{Key: []byte("b")}, id2, ackMgr, nil, false)
+
+	if first != second {
+		t.Fatal("expected the acked wrapper to be reused by the pool")
+	}
+	if !bytes.Equal(second.Key(), []byte("b")) {
+		t.Fatalf("expected reused wrapper to reflect the new message's key, got %q", second.Key())
+	}
+}
@@ -33,12 +33,18 @@ import (
 type (
 	mockSaramaConsumer struct {
 		sync.Mutex
-		closed     int64
-		offsets    map[int32]int64
-		errorC     chan error
-		notifyC    chan *cluster.Notification
-		partitionC chan cluster.PartitionConsumer
-		messages   chan *sarama.ConsumerMessage
+		closed          int64
+		offsets         map[int32]int64
+		errorC          chan error
+		notifyC         chan *cluster.Notification
+		partitionC      chan cluster.PartitionConsumer
+		messages        chan *sarama.ConsumerMessage
+		topics          []string
+		resumeErr       error
+		resumeCount     int64
+		oldestOffsets   map[int32]int64
+		newestOffsets   map[int32]int64
+		partitionCounts map[string]int32
 	}
 	mockPartitionedConsumer struct {
 		id          int32
@@ -46,12 +52,16 @@ type (
 		closed      int64
 		beginOffset int64
 		msgC        chan *sarama.ConsumerMessage
+		errC        chan *sarama.ConsumerError
 	}
 	mockDLQProducer struct {
 		sync.Mutex
-		closed int64
-		size   int
-		keys   map[string]struct{}
+		closed   int64
+		size     int
+		keys     map[string]struct{}
+		lastMsg  *sarama.ProducerMessage
+		poisoned map[string]struct{}
+		attempts map[string]int
 	}
 )
 
@@ -61,6 +71,7 @@ func newMockPartitionedConsumer(topic string, id int32, beginOffset int64, rcvBu
 		topic:       topic,
 		beginOffset: beginOffset,
 		msgC:        make(chan *sarama.ConsumerMessage, rcvBufSize),
+		errC:        make(chan *sarama.ConsumerError),
 	}
 }
 
@@ -98,6 +109,27 @@ func (m *mockPartitionedConsumer) Close() error {
 	return nil
 }
 
+// AsyncClose triggers a close without waiting for it to complete.
+func (m *mockPartitionedConsumer) AsyncClose() {
+	atomic.StoreInt64(&m.closed, 1)
+}
+
+// Errors returns the read channel for errors that occur during consuming.
+func (m *mockPartitionedConsumer) Errors() <-chan *sarama.ConsumerError {
+	return m.errC
+}
+
+// InitialOffset returns the offset used for creating this PartitionConsumer instance.
+func (m *mockPartitionedConsumer) InitialOffset() int64 {
+	return m.beginOffset
+}
+
+// MarkOffset marks the offset of a message as processed.
+func (m *mockPartitionedConsumer) MarkOffset(offset int64, metadata string) {}
+
+// ResetOffset resets the offset to a previously processed message.
+func (m *mockPartitionedConsumer) ResetOffset(offset int64, metadata string) {}
+
 func (m *mockPartitionedConsumer) isClosed() bool {
 	return atomic.LoadInt64(&m.closed) == 1
 }
@@ -197,14 +229,121 @@ func (m *mockSaramaConsumer) isClosed() bool {
 	return atomic.LoadInt64(&m.closed) == 1
 }
 
+func (m *mockSaramaConsumer) Topics() ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.topics, nil
+}
+
+func (m *mockSaramaConsumer) ResumePartition(topic string, partition int32, offset int64) (cluster.PartitionConsumer, error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.resumeErr != nil {
+		err := m.resumeErr
+		m.resumeErr = nil
+		return nil, err
+	}
+	atomic.AddInt64(&m.resumeCount, 1)
+	return newMockPartitionedConsumer(topic, partition, offset-1, 100), nil
+}
+
+func (m *mockSaramaConsumer) setTopics(topics []string) {
+	m.Lock()
+	defer m.Unlock()
+	m.topics = topics
+}
+
+// setResumeErr makes the next call to ResumePartition fail with err, then
+// reverts to succeeding.
+func (m *mockSaramaConsumer) setResumeErr(err error) {
+	m.Lock()
+	defer m.Unlock()
+	m.resumeErr = err
+}
+
+func (m *mockSaramaConsumer) resumeAttempts() int64 {
+	return atomic.LoadInt64(&m.resumeCount)
+}
+
+// CommittedOffset returns the offset MarkPartitionOffset/MarkOffset was
+// last called with for partition, or -1 if it has never been called.
+func (m *mockSaramaConsumer) CommittedOffset(topic string, partition int32) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	off, ok := m.offsets[partition]
+	if !ok {
+		return -1, nil
+	}
+	return off, nil
+}
+
+func (m *mockSaramaConsumer) OldestOffset(topic string, partition int32) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.oldestOffsets[partition], nil
+}
+
+func (m *mockSaramaConsumer) NewestOffset(topic string, partition int32) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.newestOffsets[partition], nil
+}
+
+// setOldestOffset configures the value OldestOffset returns for partition.
+func (m *mockSaramaConsumer) setOldestOffset(partition int32, offset int64) {
+	m.Lock()
+	defer m.Unlock()
+	if m.oldestOffsets == nil {
+		m.oldestOffsets = make(map[int32]int64)
+	}
+	m.oldestOffsets[partition] = offset
+}
+
+// setNewestOffset configures the value NewestOffset returns for partition.
+func (m *mockSaramaConsumer) setNewestOffset(partition int32, offset int64) {
+	m.Lock()
+	defer m.Unlock()
+	if m.newestOffsets == nil {
+		m.newestOffsets = make(map[int32]int64)
+	}
+	m.newestOffsets[partition] = offset
+}
+
+// TopicPartitions returns the partitions configured for topic via
+// setPartitionCount, defaulting to none if it was never configured.
+func (m *mockSaramaConsumer) TopicPartitions(topic string) ([]int32, error) {
+	m.Lock()
+	defer m.Unlock()
+	count := m.partitionCounts[topic]
+	partitions := make([]int32, count)
+	for i := range partitions {
+		partitions[i] = int32(i)
+	}
+	return partitions, nil
+}
+
+// setPartitionCount configures the total number of partitions TopicPartitions
+// reports for topic, independent of how many are assigned to this member.
+func (m *mockSaramaConsumer) setPartitionCount(topic string, count int32) {
+	m.Lock()
+	defer m.Unlock()
+	if m.partitionCounts == nil {
+		m.partitionCounts = make(map[string]int32)
+	}
+	m.partitionCounts[topic] = count
+}
+
 func newMockDLQProducer() *mockDLQProducer {
 	return &mockDLQProducer{
-		keys: make(map[string]struct{}),
+		keys:     make(map[string]struct{}),
+		poisoned: make(map[string]struct{}),
+		attempts: make(map[string]int),
 	}
 }
 func (d *mockDLQProducer) SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error) {
 	d.Lock()
 	defer d.Unlock()
+	d.lastMsg = msg
 	key := string(msg.Key.(sarama.StringEncoder))
 	if d.size < 5 {
 		// for the first few messages throw errors to test backoff/retry
@@ -218,8 +357,38 @@ func (d *mockDLQProducer) SendMessage(msg *sarama.ProducerMessage) (partition in
 	return 0, 0, nil
 }
 
+// SendMessages simulates a batch send where the first encounter of each
+// distinct key within the first 5 accepted messages fails, mirroring
+// SendMessage's intermittent-error behavior but at batch granularity, and
+// returns a sarama.ProducerErrors so callers can exercise partial-batch
+// retry logic. Keys registered via poisonKey always fail, regardless of
+// how many messages have already succeeded, so callers can exercise the
+// exceeds-max-retries drop path.
 func (d *mockDLQProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
-	return fmt.Errorf("not supported")
+	d.Lock()
+	defer d.Unlock()
+	var errs sarama.ProducerErrors
+	for _, msg := range msgs {
+		key := string(msg.Key.(sarama.StringEncoder))
+		d.attempts[key]++
+		if _, ok := d.poisoned[key]; ok {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: fmt.Errorf("poisoned key")})
+			continue
+		}
+		if d.size < 5 {
+			if _, ok := d.keys[key]; !ok {
+				d.keys[key] = struct{}{}
+				errs = append(errs, &sarama.ProducerError{Msg: msg, Err: fmt.Errorf("intermittent batch error")})
+				continue
+			}
+		}
+		d.size++
+		d.keys[key] = struct{}{}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 func (d *mockDLQProducer) Close() error {
 	d.Lock()
@@ -236,4 +405,40 @@ func (d *mockDLQProducer) backlog() int {
 	d.Lock()
 	defer d.Unlock()
 	return d.size
-}
\ No newline at end of file
+}
+
+// lastSent returns the most recent message passed to SendMessage,
+// regardless of whether that call reported success or an intermittent
+// error - useful for asserting on the bytes a caller handed over.
+func (d *mockDLQProducer) lastSent() *sarama.ProducerMessage {
+	d.Lock()
+	defer d.Unlock()
+	return d.lastMsg
+}
+
+// seedSucceeded marks key as already having succeeded, as if an earlier
+// SendMessages call had accepted it - letting a test mix a pre-succeeded
+// key with a brand-new one in the same batch to force a genuine partial
+// failure within a single SendMessages call.
+func (d *mockDLQProducer) seedSucceeded(key string) {
+	d.Lock()
+	defer d.Unlock()
+	d.keys[key] = struct{}{}
+	d.size++
+}
+
+// poisonKey makes every future SendMessages call fail for key, no matter
+// how many other messages have already succeeded.
+func (d *mockDLQProducer) poisonKey(key string) {
+	d.Lock()
+	defer d.Unlock()
+	d.poisoned[key] = struct{}{}
+}
+
+// attemptsFor returns how many times SendMessages has been asked to send
+// key, successful or not.
+func (d *mockDLQProducer) attemptsFor(key string) int {
+	d.Lock()
+	defer d.Unlock()
+	return d.attempts[key]
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// ErrOffsetOutOfRange is returned by ConsumerGroupOffsetsChecker when
+	// the offset committed for the consumer group falls outside the
+	// broker's retained log - either aged off the low end, or (Newest
+	// set, Oldest zero) ahead of the log end, e.g. because the topic was
+	// deleted and recreated with fewer messages. Either way the broker
+	// will silently reset consumption to OffsetOldest/OffsetNewest and
+	// messages the group believes it still owns will never be delivered.
+	ErrOffsetOutOfRange struct {
+		Topic     string
+		Partition int32
+		Committed int64
+		Oldest    int64
+		Newest    int64
+	}
+
+	// ConsumerGroupOffsetsChecker verifies, before a partitionConsumer
+	// starts delivering messages, that the offset committed to the broker
+	// for its [topic, partition] is still within the retained log. This
+	// mirrors the check Knative's eventing-kafka consumergroup does before
+	// reporting a subscription ready.
+	ConsumerGroupOffsetsChecker struct {
+		sarama SaramaConsumer
+		logger *zap.Logger
+	}
+)
+
+func (e *ErrOffsetOutOfRange) Error() string {
+	if e.Committed >= e.Oldest && e.Committed > e.Newest {
+		return fmt.Sprintf(
+			"kafka: committed offset %v for %v-%v is ahead of the broker's log end %v, messages would be skipped",
+			e.Committed, e.Topic, e.Partition, e.Newest,
+		)
+	}
+	return fmt.Sprintf(
+		"kafka: committed offset %v for %v-%v is behind the earliest retained offset %v, messages would be skipped",
+		e.Committed, e.Topic, e.Partition, e.Oldest,
+	)
+}
+
+// newConsumerGroupOffsetsChecker returns a checker that queries sarama for
+// broker-side offset state.
+func newConsumerGroupOffsetsChecker(sarama SaramaConsumer, logger *zap.Logger) *ConsumerGroupOffsetsChecker {
+	return &ConsumerGroupOffsetsChecker{
+		sarama: sarama,
+		logger: logger,
+	}
+}
+
+// Check verifies that the group's committed offset for [topic, partition]
+// has not already expired off the broker's log. A group that has never
+// committed an offset for this partition (CommittedOffset returns -1) is
+// not at risk of skipping messages it has already seen, so it passes.
+func (c *ConsumerGroupOffsetsChecker) Check(topic string, partition int32) error {
+	committed, err := c.sarama.CommittedOffset(topic, partition)
+	if err != nil {
+		return err
+	}
+	if committed < 0 {
+		return nil
+	}
+
+	oldest, err := c.sarama.OldestOffset(topic, partition)
+	if err != nil {
+		return err
+	}
+	if committed < oldest {
+		return &ErrOffsetOutOfRange{
+			Topic:     topic,
+			Partition: partition,
+			Committed: committed,
+			Oldest:    oldest,
+		}
+	}
+
+	newest, err := c.sarama.NewestOffset(topic, partition)
+	if err != nil {
+		return err
+	}
+	if committed > newest {
+		return &ErrOffsetOutOfRange{
+			Topic:     topic,
+			Partition: partition,
+			Committed: committed,
+			Newest:    newest,
+		}
+	}
+
+	c.logger.Debug("consumer group offsets verified",
+		zap.String("topic", topic), zap.Int32("partition", partition),
+		zap.Int64("committed", committed), zap.Int64("oldest", oldest), zap.Int64("newest", newest))
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestConsumerGroupOffsetsCheckerPassesWithNoPriorCommit(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.setOldestOffset(0, 100)
+
+	checker := newConsumerGroupOffsetsChecker(sarama, zap.NewNop())
+	if err := checker.Check("orders", 0); err != nil {
+		t.Fatalf("expected no error for a group with no prior commit, got: %v", err)
+	}
+}
+
+func TestConsumerGroupOffsetsCheckerPassesWhenCommittedWithinRetainedLog(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.MarkPartitionOffset("orders", 0, 150, "")
+	sarama.setOldestOffset(0, 100)
+	sarama.setNewestOffset(0, 200)
+
+	checker := newConsumerGroupOffsetsChecker(sarama, zap.NewNop())
+	if err := checker.Check("orders", 0); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestConsumerGroupOffsetsCheckerFailsWhenCommittedOffsetAheadOfLog(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.MarkPartitionOffset("orders", 0, 200, "")
+	sarama.setOldestOffset(0, 100)
+	sarama.setNewestOffset(0, 150)
+
+	checker := newConsumerGroupOffsetsChecker(sarama, zap.NewNop())
+	err := checker.Check("orders", 0)
+	if err == nil {
+		t.Fatal("expected an error when the committed offset is ahead of the broker's log end")
+	}
+	outOfRange, ok := err.(*ErrOffsetOutOfRange)
+	if !ok {
+		t.Fatalf("expected *ErrOffsetOutOfRange, got %T: %v", err, err)
+	}
+	if outOfRange.Committed != 200 || outOfRange.Newest != 150 {
+		t.Fatalf("unexpected error fields: %+v", outOfRange)
+	}
+}
+
+func TestConsumerGroupOffsetsCheckerFailsWhenCommittedOffsetExpired(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.MarkPartitionOffset("orders", 0, 50, "")
+	sarama.setOldestOffset(0, 100)
+
+	checker := newConsumerGroupOffsetsChecker(sarama, zap.NewNop())
+	err := checker.Check("orders", 0)
+	if err == nil {
+		t.Fatal("expected an error when the committed offset has aged out of the retained log")
+	}
+	outOfRange, ok := err.(*ErrOffsetOutOfRange)
+	if !ok {
+		t.Fatalf("expected *ErrOffsetOutOfRange, got %T: %v", err, err)
+	}
+	if outOfRange.Committed != 50 || outOfRange.Oldest != 100 {
+		t.Fatalf("unexpected error fields: %+v", outOfRange)
+	}
+}
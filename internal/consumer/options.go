@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import "time"
+
+type (
+	// Options are the runtime options for a consumer / partitionConsumer.
+	Options struct {
+		// Concurrency is the number of goroutines used to process messages
+		Concurrency int
+
+		// RcvBufferSize is the internal message buffer size
+		RcvBufferSize int
+
+		// MaxProcessingTime is the max time a message can spend in processing,
+		// used as the checkpoint interval
+		MaxProcessingTime time.Duration
+
+		// OffsetCommitInterval is the interval for committing offsets to the broker
+		OffsetCommitInterval time.Duration
+
+		// RebalanceDwellTime is the amount of time to wait after a rebalance
+		// before resuming message delivery
+		RebalanceDwellTime time.Duration
+
+		// MetadataRefreshInterval is how often a topic-pattern subscription
+		// re-queries cluster metadata to discover newly created or deleted
+		// topics. Ignored for a static TopicList subscription.
+		MetadataRefreshInterval time.Duration
+
+		// RebalanceStrategy controls how partitions are assigned across
+		// members of the consumer group. Defaults to Range.
+		RebalanceStrategy RebalanceStrategy
+
+		// ReconnectBackoff controls the retry delay used when a partition's
+		// cluster.PartitionConsumer closes unexpectedly and must be resumed
+		// without waiting for the next group rebalance. Defaults to
+		// DefaultReconnectBackoff.
+		ReconnectBackoff *SimpleBackoff
+
+		// DLQBatchSize is the number of messages a batchDLQ accumulates
+		// before flushing via SyncProducer.SendMessages.
+		DLQBatchSize int
+
+		// DLQFlushInterval is the maximum amount of time a batchDLQ holds
+		// messages before flushing, even if DLQBatchSize hasn't been reached.
+		DLQFlushInterval time.Duration
+
+		// DLQMaxRetries is the number of times a batchDLQ retries a message
+		// that failed as part of a batch before giving up on it.
+		DLQMaxRetries int
+
+		// ZeroCopy, when true, hands applications a kafka.Message whose
+		// Key/Value alias the underlying sarama.ConsumerMessage directly
+		// instead of a defensive copy owned by the pooled wrapper. This
+		// avoids an allocation and a copy per message, but the application
+		// must not retain the returned slices past Ack()/Nack().
+		ZeroCopy bool
+	}
+)
+
+// DefaultOptions returns the default options used if the application does
+// not specify its own.
+func DefaultOptions() *Options {
+	return &Options{
+		Concurrency:             1000,
+		RcvBufferSize:           2000,
+		MaxProcessingTime:       time.Second,
+		OffsetCommitInterval:    time.Second,
+		RebalanceDwellTime:      time.Duration(0),
+		MetadataRefreshInterval: time.Minute,
+		RebalanceStrategy:       Range,
+		ReconnectBackoff:        DefaultReconnectBackoff,
+		DLQBatchSize:            100,
+		DLQFlushInterval:        time.Second,
+		DLQMaxRetries:           3,
+	}
+}
@@ -22,6 +22,8 @@ package consumer
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"math"
@@ -41,18 +43,24 @@ type (
 	// partitionConsumer is the consumer for a specific
 	// kafka partition
 	partitionConsumer struct {
-		id        int32
-		topic     string
-		msgCh     chan kafka.Message
-		ackMgr    *ackManager
-		sarama    SaramaConsumer
-		pConsumer cluster.PartitionConsumer
-		dlq       DLQ
-		options   *Options
-		tally     tally.Scope
-		logger    *zap.Logger
-		stopC     chan struct{}
-		lifecycle *util.RunLifecycle
+		id          int32
+		topic       string
+		msgCh       chan kafka.Message
+		ackMgr      *ackManager
+		sarama      SaramaConsumer
+		pConsumerMu sync.RWMutex
+		pConsumer   cluster.PartitionConsumer
+		dlq         DLQ
+		options     *Options
+		tally       tally.Scope
+		logger      *zap.Logger
+		stopC       chan struct{}
+		readyC      chan struct{}
+		lifecycle   *util.RunLifecycle
+		state       int32 // atomic, one of kafka.PartitionState*
+		events      chan<- kafka.PartitionEvent
+		checker     *ConsumerGroupOffsetsChecker
+		msgPool     *sync.Pool
 	}
 )
 
@@ -65,7 +73,8 @@ func newPartitionConsumer(
 	msgCh chan kafka.Message,
 	dlq DLQ,
 	scope tally.Scope,
-	logger *zap.Logger) *partitionConsumer {
+	logger *zap.Logger,
+	events chan<- kafka.PartitionEvent) *partitionConsumer {
 	maxUnAcked := options.Concurrency + options.RcvBufferSize + 1
 	name := fmt.Sprintf("%v-partition-%v", pConsumer.Topic(), pConsumer.Partition())
 	return &partitionConsumer{
@@ -79,16 +88,63 @@ func newPartitionConsumer(
 		tally:     scope.Tagged(map[string]string{"partition": strconv.Itoa(int(pConsumer.Partition()))}),
 		logger:    logger,
 		stopC:     make(chan struct{}),
+		readyC:    make(chan struct{}),
 		ackMgr:    newAckManager(maxUnAcked, scope, logger),
 		lifecycle: util.NewRunLifecycle(name, logger),
+		state:     int32(kafka.PartitionStateRunning),
+		events:    events,
+		checker:   newConsumerGroupOffsetsChecker(sarama, logger),
+		msgPool:   &sync.Pool{New: func() interface{} { return &message{} }},
 	}
 }
 
-// Start starts the consumer
+// Ready returns a channel that is closed once this partition consumer has
+// verified its offsets and begun delivering messages.
+func (p *partitionConsumer) Ready() <-chan struct{} {
+	return p.readyC
+}
+
+// State returns the partition consumer's current lifecycle state.
+func (p *partitionConsumer) State() kafka.PartitionState {
+	return kafka.PartitionState(atomic.LoadInt32(&p.state))
+}
+
+func (p *partitionConsumer) setState(s kafka.PartitionState) {
+	atomic.StoreInt32(&p.state, int32(s))
+	if p.events == nil {
+		return
+	}
+	select {
+	case p.events <- kafka.PartitionEvent{Topic: p.topic, Partition: p.id, State: s}:
+	default:
+	}
+}
+
+func (p *partitionConsumer) currentPConsumer() cluster.PartitionConsumer {
+	p.pConsumerMu.RLock()
+	defer p.pConsumerMu.RUnlock()
+	return p.pConsumer
+}
+
+func (p *partitionConsumer) setPConsumer(pConsumer cluster.PartitionConsumer) {
+	p.pConsumerMu.Lock()
+	defer p.pConsumerMu.Unlock()
+	p.pConsumer = pConsumer
+}
+
+// Start starts the consumer. It blocks until the partition's committed
+// offset has been verified against the broker's retained log, so that a
+// caller waiting on Ready (directly, or transitively via
+// kafka.Consumer.WaitForReady) never observes readiness before it is safe
+// to assume no messages will be silently skipped.
 func (p *partitionConsumer) Start() error {
 	return p.lifecycle.Start(func() error {
+		if err := p.checker.Check(p.topic, p.id); err != nil {
+			return err
+		}
 		go p.messageLoop()
 		go p.commitLoop()
+		close(p.readyC)
 		p.tally.Counter(metrics.KafkaPartitionStarted).Inc(1)
 		return nil
 	})
@@ -110,16 +166,21 @@ func (p *partitionConsumer) Drain(d time.Duration) {
 }
 
 // messageLoop is the message read loop for this consumer
-// todo: maintain a pre-allocated pool of Messages
 func (p *partitionConsumer) messageLoop() {
 	p.logInfo("partition consumer started")
 	for {
 		select {
-		case m, ok := <-p.pConsumer.Messages():
+		case m, ok := <-p.currentPConsumer().Messages():
 			if !ok {
-				p.logInfo("partition message channel closed")
-				p.Drain(p.options.MaxProcessingTime)
-				return
+				if p.isStopping() {
+					p.logInfo("partition message channel closed")
+					p.Drain(p.options.MaxProcessingTime)
+					return
+				}
+				if !p.reconnect() {
+					return
+				}
+				continue
 			}
 			lag := time.Now().Sub(m.Timestamp)
 			p.tally.Gauge(metrics.KafkaPartitionLag).Update(float64(lag))
@@ -133,6 +194,42 @@ func (p *partitionConsumer) messageLoop() {
 	}
 }
 
+// isStopping reports whether Stop/Drain has already been requested.
+func (p *partitionConsumer) isStopping() bool {
+	select {
+	case <-p.stopC:
+		return true
+	default:
+		return false
+	}
+}
+
+// reconnect requests a fresh cluster.PartitionConsumer for this partition,
+// starting just past the last committed offset, retrying with backoff
+// until it succeeds or the consumer is stopped. Returns false if the
+// consumer was stopped while reconnecting.
+func (p *partitionConsumer) reconnect() bool {
+	p.setState(kafka.PartitionStateReconnecting)
+	p.logInfo("partition consumer reconnecting")
+	for attempt := 0; ; attempt++ {
+		if p.sleep(p.options.ReconnectBackoff.Duration(attempt)) {
+			return false
+		}
+		p.tally.Counter(metrics.KafkaPartitionReconnect).Inc(1)
+		pConsumer, err := p.sarama.ResumePartition(p.topic, p.id, p.ackMgr.CommitLevel()+1)
+		if err != nil {
+			p.logger.Error("partition reconnect attempt failed",
+				zap.String("topic", p.topic), zap.Int32("partition", p.id),
+				zap.Int("attempt", attempt), zap.Error(err))
+			continue
+		}
+		p.setPConsumer(pConsumer)
+		p.setState(kafka.PartitionStateRunning)
+		p.logInfo("partition consumer reconnected")
+		return true
+	}
+}
+
 // commitLoop periodically checkpoints the offsets with broker
 func (p *partitionConsumer) commitLoop() {
 	ticker := time.NewTicker(p.options.MaxProcessingTime)
@@ -154,7 +251,7 @@ func (p *partitionConsumer) markOffset() {
 	if latestOff >= 0 {
 		p.sarama.MarkPartitionOffset(p.topic, p.id, latestOff, "")
 		p.tally.Gauge(metrics.KafkaPartitionCommitOffset).Update(float64(latestOff))
-		backlog := math.Max(float64(0), float64(p.pConsumer.HighWaterMarkOffset()-latestOff))
+		backlog := math.Max(float64(0), float64(p.currentPConsumer().HighWaterMarkOffset()-latestOff))
 		p.tally.Gauge(metrics.KafkaPartitionBacklog).Update(backlog)
 		p.logger.Debug("kafka checkpoint",
 			zap.String("topic", p.topic), zap.Int32("partition", p.id), zap.Int64("offset", latestOff))
@@ -167,7 +264,7 @@ func (p *partitionConsumer) deliver(scm *sarama.ConsumerMessage) {
 	if err != nil {
 		return
 	}
-	msg := newMessage(scm, ackID, p.ackMgr, p.dlq)
+	msg := newMessage(p.msgPool, scm, ackID, p.ackMgr, p.dlq, p.options.ZeroCopy)
 	select {
 	case p.msgCh <- msg:
 		return
@@ -205,7 +302,8 @@ func (p *partitionConsumer) stop(d time.Duration) {
 		close(p.stopC)
 		time.Sleep(d)
 		p.markOffset()
-		p.pConsumer.Close()
+		p.currentPConsumer().Close()
+		p.setState(kafka.PartitionStateStopped)
 		p.tally.Counter(metrics.KafkaPartitionStopped).Inc(1)
 	})
 }
@@ -221,4 +319,4 @@ func (p *partitionConsumer) sleep(d time.Duration) bool {
 
 func (p *partitionConsumer) logInfo(msg string) {
 	p.logger.Info(msg, zap.String("topic", p.topic), zap.Int32("partition", p.id))
-}
\ No newline at end of file
+}
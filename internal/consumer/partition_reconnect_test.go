@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber-go/kafka-client/kafka"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+func TestPartitionConsumerReconnectsOnUnexpectedClose(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	pConsumer := newMockPartitionedConsumer("orders", 0, 0, 10)
+
+	opts := DefaultOptions()
+	opts.ReconnectBackoff = &SimpleBackoff{
+		InitialInterval: time.Millisecond,
+		Multiplier:      1,
+		MaxInterval:     time.Millisecond,
+		Jitter:          0,
+	}
+	events := make(chan kafka.PartitionEvent, 10)
+
+	pc := newPartitionConsumer(sarama, pConsumer, opts, make(chan kafka.Message, 10), nil, tally.NoopScope, zap.NewNop(), events)
+	if err := pc.Start(); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	defer pc.Stop()
+
+	// simulate an unexpected broker disconnect
+	pConsumer.stop()
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.State == kafka.PartitionStateRunning && sarama.resumeAttempts() > 0 {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("expected partition consumer to reconnect, last state=%v resumeAttempts=%d", pc.State(), sarama.resumeAttempts())
+		}
+	}
+}
+
+func TestPartitionConsumerStartFailsWhenCommittedOffsetExpired(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.MarkPartitionOffset("orders", 0, 50, "")
+	sarama.setOldestOffset(0, 100)
+	pConsumer := newMockPartitionedConsumer("orders", 0, 0, 10)
+
+	pc := newPartitionConsumer(sarama, pConsumer, DefaultOptions(), make(chan kafka.Message, 10), nil, tally.NoopScope, zap.NewNop(), nil)
+	err := pc.Start()
+	if err == nil {
+		t.Fatal("expected Start to fail when the committed offset has aged out of the retained log")
+	}
+	if _, ok := err.(*ErrOffsetOutOfRange); !ok {
+		t.Fatalf("expected *ErrOffsetOutOfRange, got %T: %v", err, err)
+	}
+	select {
+	case <-pc.Ready():
+		t.Fatal("did not expect Ready to be closed after a failed Start")
+	default:
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"fmt"
+
+	cluster "github.com/bsm/sarama-cluster"
+)
+
+type (
+	// RebalanceStrategy decides how partitions are distributed across the
+	// members of a consumer group and, where applicable, validates that a
+	// group's current topic subscription is compatible with that strategy.
+	RebalanceStrategy interface {
+		// Name identifies the strategy in logs and metrics
+		Name() string
+		// Validate is called whenever the set of [topic -> partition count]
+		// for a group's subscription changes. Strategies that don't care
+		// about cross-topic structure (Range, RoundRobin) always return nil.
+		Validate(topicPartitionCounts map[string]int32) error
+
+		// saramaStrategy returns the sarama-cluster partition strategy this
+		// RebalanceStrategy maps onto.
+		saramaStrategy() cluster.Strategy
+	}
+
+	baseStrategy struct {
+		name   string
+		sarama cluster.Strategy
+	}
+
+	// copartitionedStrategy additionally requires that every subscribed
+	// topic have the same number of partitions, so that partition N of
+	// every topic lands on the same group member.
+	copartitionedStrategy struct {
+		baseStrategy
+	}
+
+	// ErrCopartitionMismatch is returned by Copartitioned.Validate when the
+	// group's subscribed topics do not all have the same partition count.
+	ErrCopartitionMismatch struct {
+		Topic              string
+		PartitionCount     int32
+		ExpectedTopic      string
+		ExpectedPartitions int32
+	}
+)
+
+func (b baseStrategy) Name() string                     { return b.name }
+func (b baseStrategy) saramaStrategy() cluster.Strategy { return b.sarama }
+func (b baseStrategy) Validate(map[string]int32) error  { return nil }
+
+func (e *ErrCopartitionMismatch) Error() string {
+	return fmt.Sprintf("copartitioned rebalance strategy: topic %q has %d partitions, expected %d (matching %q)",
+		e.Topic, e.PartitionCount, e.ExpectedPartitions, e.ExpectedTopic)
+}
+
+func (copartitionedStrategy) Validate(topicPartitionCounts map[string]int32) error {
+	var refTopic string
+	var refCount int32 = -1
+	for topic, count := range topicPartitionCounts {
+		if refCount == -1 {
+			refTopic, refCount = topic, count
+			continue
+		}
+		if count != refCount {
+			return &ErrCopartitionMismatch{
+				Topic:              topic,
+				PartitionCount:     count,
+				ExpectedTopic:      refTopic,
+				ExpectedPartitions: refCount,
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	// Range assigns each consumer a contiguous range of partitions per topic.
+	// This is the sarama-cluster default.
+	Range RebalanceStrategy = baseStrategy{name: "range", sarama: cluster.StrategyRange}
+
+	// RoundRobin assigns partitions to consumers round-robin across all
+	// subscribed topics.
+	RoundRobin RebalanceStrategy = baseStrategy{name: "roundrobin", sarama: cluster.StrategyRoundRobin}
+
+	// Copartitioned behaves like Range, but additionally validates that
+	// every topic in the group's subscription has an identical partition
+	// count. Because sarama-cluster's range strategy assigns partition N
+	// of every topic to the same member index, equal partition counts are
+	// sufficient to guarantee that partition N of every subscribed topic
+	// lands on the same group member - enabling stateful joins/aggregations
+	// across topics without cross-member coordination.
+	Copartitioned RebalanceStrategy = copartitionedStrategy{baseStrategy{name: "copartitioned", sarama: cluster.StrategyRange}}
+)
+
+// applyRebalanceStrategy configures cfg's partition assignment strategy to
+// match strategy, defaulting to Range if strategy is nil.
+func applyRebalanceStrategy(cfg *cluster.Config, strategy RebalanceStrategy) {
+	if strategy == nil {
+		strategy = Range
+	}
+	cfg.Group.PartitionStrategy = strategy.saramaStrategy()
+}
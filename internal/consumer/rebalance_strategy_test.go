@@ -0,0 +1,104 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	cluster "github.com/bsm/sarama-cluster"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+func TestCopartitionedValidateAcceptsEqualPartitionCounts(t *testing.T) {
+	err := Copartitioned.Validate(map[string]int32{"orders": 8, "payments": 8})
+	if err != nil {
+		t.Fatalf("expected no error for equal partition counts, got %v", err)
+	}
+}
+
+func TestCopartitionedValidateRejectsMismatchedPartitionCounts(t *testing.T) {
+	err := Copartitioned.Validate(map[string]int32{"orders": 8, "payments": 4})
+	if err == nil {
+		t.Fatal("expected an error for mismatched partition counts")
+	}
+	if _, ok := err.(*ErrCopartitionMismatch); !ok {
+		t.Fatalf("expected *ErrCopartitionMismatch, got %T", err)
+	}
+}
+
+func TestGroupAssignmentMonitorSurfacesCopartitionMismatch(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.setPartitionCount("orders", 4)
+	sarama.setPartitionCount("payments", 2)
+	monitor := newGroupAssignmentMonitor(sarama, Copartitioned, tally.NoopScope, zap.NewNop())
+	monitor.Start()
+	defer monitor.Stop()
+
+	sarama.notifyC <- &cluster.Notification{
+		Current: map[string][]int32{
+			"orders":   {0, 1, 2, 3},
+			"payments": {0, 1},
+		},
+	}
+
+	select {
+	case err := <-monitor.Errors():
+		if _, ok := err.(*ErrCopartitionMismatch); !ok {
+			t.Fatalf("expected *ErrCopartitionMismatch, got %T", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a copartition mismatch error to be published")
+	}
+}
+
+// TestGroupAssignmentMonitorUsesWholeTopicPartitionCounts guards against
+// validating off this member's own assigned share of each topic (current),
+// which can coincidentally be equal across topics whose true partition
+// counts differ - e.g. a 3-member Range-strategy group where one member
+// holds 3 of a 10-partition topic and 3 of a 9-partition topic.
+func TestGroupAssignmentMonitorUsesWholeTopicPartitionCounts(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.setPartitionCount("orders", 10)
+	sarama.setPartitionCount("payments", 9)
+	monitor := newGroupAssignmentMonitor(sarama, Copartitioned, tally.NoopScope, zap.NewNop())
+	monitor.Start()
+	defer monitor.Stop()
+
+	// This member's own assigned share happens to be equal-sized for both
+	// topics, even though the topics' true partition counts differ.
+	sarama.notifyC <- &cluster.Notification{
+		Current: map[string][]int32{
+			"orders":   {0, 1, 2},
+			"payments": {0, 1, 2},
+		},
+	}
+
+	select {
+	case err := <-monitor.Errors():
+		if _, ok := err.(*ErrCopartitionMismatch); !ok {
+			t.Fatalf("expected *ErrCopartitionMismatch, got %T", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a copartition mismatch error to be published even though this member's own assigned counts match")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"github.com/Shopify/sarama"
+	cluster "github.com/bsm/sarama-cluster"
+)
+
+type (
+	// SaramaConsumer is the interface implemented by the underlying
+	// sarama-cluster consumer group client. It is the seam that lets
+	// tests substitute a mockSaramaConsumer.
+	SaramaConsumer interface {
+		Errors() <-chan error
+		Notifications() <-chan *cluster.Notification
+		Partitions() <-chan cluster.PartitionConsumer
+		CommitOffsets() error
+		Messages() <-chan *sarama.ConsumerMessage
+		MarkOffset(msg *sarama.ConsumerMessage, metadata string)
+		MarkPartitionOffset(topic string, partition int32, offset int64, metadata string)
+		HighWaterMarks() map[string]map[int32]int64
+		Close() error
+
+		// Topics returns the set of topics this consumer's current
+		// subscription resolves to. For a static TopicList this is
+		// fixed; for a TopicPattern subscription it reflects the
+		// result of the most recent metadata refresh.
+		Topics() ([]string, error)
+
+		// ResumePartition returns a fresh cluster.PartitionConsumer for
+		// [topic, partition], seeked to offset. It is used by
+		// partitionConsumer to reconnect a partition whose
+		// cluster.PartitionConsumer closed unexpectedly, without waiting
+		// for the next group rebalance.
+		ResumePartition(topic string, partition int32, offset int64) (cluster.PartitionConsumer, error)
+
+		// CommittedOffset returns the offset currently committed to the
+		// broker for [topic, partition] for this consumer's group, or -1
+		// if the group has never committed an offset for it.
+		CommittedOffset(topic string, partition int32) (int64, error)
+
+		// OldestOffset returns the earliest offset still available on the
+		// broker for [topic, partition].
+		OldestOffset(topic string, partition int32) (int64, error)
+
+		// NewestOffset returns the offset that will be assigned to the
+		// next message produced to [topic, partition].
+		NewestOffset(topic string, partition int32) (int64, error)
+
+		// TopicPartitions returns every partition id that exists for topic
+		// on the cluster, regardless of how many (if any) are currently
+		// assigned to this group member. Used to validate whole-topic
+		// partition counts, e.g. for a Copartitioned RebalanceStrategy.
+		TopicPartitions(topic string) ([]int32, error)
+	}
+)
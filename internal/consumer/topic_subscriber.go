@@ -0,0 +1,303 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	cluster "github.com/bsm/sarama-cluster"
+	"github.com/uber-go/kafka-client/internal/util"
+	"github.com/uber-go/kafka-client/kafka"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type (
+	// DLQResolver resolves the DLQ to use for messages read from a given
+	// topic. A topicSubscriber that fans out over a TopicPattern can have
+	// more than one topic in flight at a time, so DLQ is no longer a
+	// static 1:1 mapping with the consumer.
+	DLQResolver func(topic string) DLQ
+
+	// topicSubscriber subscribes to every topic on the cluster whose name
+	// matches a regular expression, instead of a static topic list. It
+	// periodically refreshes cluster metadata and spawns/drains
+	// partitionConsumer instances as topics start or stop matching.
+	//
+	// Not yet constructed from a kafka.Consumer - wiring ConsumerConfig's
+	// TopicPattern through to a newTopicSubscriber call is deferred to the
+	// chunk that assembles the top-level Consumer implementation.
+	topicSubscriber struct {
+		sarama    SaramaConsumer
+		pattern   *regexp.Regexp
+		options   *Options
+		msgCh     chan kafka.Message
+		dlqRes    DLQResolver
+		tally     tally.Scope
+		logger    *zap.Logger
+		stopC     chan struct{}
+		lifecycle *util.RunLifecycle
+		events    chan<- kafka.PartitionEvent
+		errC      chan error
+
+		mu       sync.Mutex
+		topics   map[string]struct{}                     // currently matched topics
+		consumer map[string]map[int32]*partitionConsumer // topic -> partition -> consumer
+	}
+)
+
+// readyPollInterval is how often WaitForReady re-checks partition
+// readiness while waiting for all tracked partitions to verify offsets.
+const readyPollInterval = 25 * time.Millisecond
+
+// newTopicSubscriber returns a subscriber that tracks every topic matching
+// pattern and keeps a partitionConsumer running per [topic, partition].
+func newTopicSubscriber(
+	sarama SaramaConsumer,
+	pattern string,
+	options *Options,
+	msgCh chan kafka.Message,
+	dlqRes DLQResolver,
+	scope tally.Scope,
+	logger *zap.Logger,
+	events chan<- kafka.PartitionEvent) (*topicSubscriber, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid topic pattern %q: %v", pattern, err)
+	}
+	return &topicSubscriber{
+		sarama:    sarama,
+		pattern:   re,
+		options:   options,
+		msgCh:     msgCh,
+		dlqRes:    dlqRes,
+		tally:     scope,
+		logger:    logger,
+		stopC:     make(chan struct{}),
+		lifecycle: util.NewRunLifecycle("topic-subscriber-"+pattern, logger),
+		events:    events,
+		errC:      make(chan error, 1),
+		topics:    make(map[string]struct{}),
+		consumer:  make(map[string]map[int32]*partitionConsumer),
+	}, nil
+}
+
+// Errors returns the channel that failures tracking an individual
+// partitionConsumer (e.g. a failed offsets check on Start) are published
+// on, so that callers don't have to infer them from a WaitForReady that
+// simply never returns.
+func (s *topicSubscriber) Errors() <-chan error {
+	return s.errC
+}
+
+// Start begins the metadata refresh loop and starts consuming
+// partitionConsumer values injected by the underlying sarama consumer.
+func (s *topicSubscriber) Start() error {
+	return s.lifecycle.Start(func() error {
+		if err := s.refresh(); err != nil {
+			return err
+		}
+		go s.refreshLoop()
+		go s.assignLoop()
+		return nil
+	})
+}
+
+// Stop drains every partitionConsumer this subscriber owns.
+func (s *topicSubscriber) Stop() {
+	s.lifecycle.Stop(func() {
+		close(s.stopC)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for topic, partitions := range s.consumer {
+			for _, pc := range partitions {
+				pc.Drain(s.options.MaxProcessingTime)
+			}
+			delete(s.consumer, topic)
+		}
+	})
+}
+
+// refreshLoop periodically re-queries cluster metadata so that topics
+// created (or deleted) after Start are picked up without a restart.
+func (s *topicSubscriber) refreshLoop() {
+	ticker := time.NewTicker(s.options.MetadataRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.refresh(); err != nil {
+				s.logger.Error("topic pattern metadata refresh failed", zap.Error(err))
+			}
+		case <-s.stopC:
+			return
+		}
+	}
+}
+
+// assignLoop drains the cluster consumer's Partitions() channel and
+// attaches a partitionConsumer to every partition whose topic currently
+// matches the subscription pattern. Partitions for topics that no longer
+// match are closed immediately - the rebalance that produced them will
+// not be re-offered until the matched-topic set includes them again.
+func (s *topicSubscriber) assignLoop() {
+	for {
+		select {
+		case pConsumer, ok := <-s.sarama.Partitions():
+			if !ok {
+				return
+			}
+			if !s.matches(pConsumer.Topic()) {
+				pConsumer.Close()
+				continue
+			}
+			s.track(pConsumer)
+		case <-s.stopC:
+			return
+		}
+	}
+}
+
+// refresh diffs the cluster's current topic list against the topics this
+// subscriber already tracks, draining any that no longer match.
+func (s *topicSubscriber) refresh() error {
+	all, err := s.sarama.Topics()
+	if err != nil {
+		return err
+	}
+	matched := make(map[string]struct{}, len(all))
+	for _, t := range all {
+		if s.pattern.MatchString(t) {
+			matched[t] = struct{}{}
+		}
+	}
+
+	s.mu.Lock()
+	removed := make(map[string]map[int32]*partitionConsumer)
+	for topic, partitions := range s.consumer {
+		if _, ok := matched[topic]; ok {
+			continue
+		}
+		removed[topic] = partitions
+		delete(s.consumer, topic)
+		delete(s.topics, topic)
+	}
+	for topic := range matched {
+		s.topics[topic] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	// Drain outside of the lock - this can block for up to
+	// MaxProcessingTime per partition and must not stall assignLoop.
+	for topic, partitions := range removed {
+		for _, pc := range partitions {
+			pc.Drain(s.options.MaxProcessingTime)
+		}
+		s.logger.Info("topic no longer matches pattern, drained", zap.String("topic", topic))
+	}
+	return nil
+}
+
+// WaitForReady blocks until every partitionConsumer currently tracked by
+// this subscriber has verified its offsets and begun delivering messages,
+// or ctx is done. Partitions assigned after WaitForReady returns are not
+// accounted for - callers that need a stronger guarantee should call it
+// again after observing further PartitionEvents.
+func (s *topicSubscriber) WaitForReady(ctx context.Context) error {
+	for {
+		if s.allReady() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+func (s *topicSubscriber) allReady() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, partitions := range s.consumer {
+		for _, pc := range partitions {
+			select {
+			case <-pc.Ready():
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *topicSubscriber) matches(topic string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.topics[topic]
+	return ok
+}
+
+// track spins up a partitionConsumer for a newly-assigned partition and
+// remembers it so a later rebalance/metadata change can drain it. Since
+// bsm/sarama-cluster can redeliver a cluster.PartitionConsumer for a
+// partition this subscriber already owns (e.g. on every rebalance, even
+// one that doesn't actually move the partition), any entry already
+// tracked for [topic, partition] is drained first so its goroutines don't
+// keep running - and consuming - alongside the new one.
+func (s *topicSubscriber) track(pConsumer cluster.PartitionConsumer) {
+	topic := pConsumer.Topic()
+	partition := pConsumer.Partition()
+	dlq := s.dlqRes(topic)
+	pc := newPartitionConsumer(s.sarama, pConsumer, s.options, s.msgCh, dlq, s.tally, s.logger, s.events)
+
+	s.mu.Lock()
+	if s.consumer[topic] == nil {
+		s.consumer[topic] = make(map[int32]*partitionConsumer)
+	}
+	existing := s.consumer[topic][partition]
+	s.consumer[topic][partition] = pc
+	s.mu.Unlock()
+
+	if existing != nil {
+		s.logger.Info("replacing partition consumer already tracked for this partition",
+			zap.String("topic", topic), zap.Int32("partition", partition))
+		existing.Drain(s.options.MaxProcessingTime)
+	}
+
+	if err := pc.Start(); err != nil {
+		s.logger.Error("partition consumer failed to start",
+			zap.String("topic", topic), zap.Int32("partition", partition), zap.Error(err))
+		s.mu.Lock()
+		if s.consumer[topic][partition] == pc {
+			delete(s.consumer[topic], partition)
+		}
+		s.mu.Unlock()
+		select {
+		case s.errC <- err:
+		default:
+		}
+	}
+}
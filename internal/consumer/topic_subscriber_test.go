@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber-go/kafka-client/kafka"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+func TestTopicSubscriberMatchesPattern(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.setTopics([]string{"orders-us", "orders-eu", "payments"})
+
+	sub, err := newTopicSubscriber(
+		sarama, "^orders-.*$", DefaultOptions(), make(chan kafka.Message, 1),
+		func(topic string) DLQ { return nil }, tally.NoopScope, zap.NewNop(), nil)
+	if err != nil {
+		t.Fatalf("newTopicSubscriber returned error: %v", err)
+	}
+	if err := sub.refresh(); err != nil {
+		t.Fatalf("refresh returned error: %v", err)
+	}
+	if !sub.matches("orders-us") || !sub.matches("orders-eu") {
+		t.Fatal("expected orders-* topics to match pattern")
+	}
+	if sub.matches("payments") {
+		t.Fatal("did not expect payments to match pattern")
+	}
+}
+
+func TestTopicSubscriberDrainsUnmatchedTopics(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.setTopics([]string{"orders-us"})
+
+	opts := DefaultOptions()
+	opts.MaxProcessingTime = time.Millisecond
+
+	sub, err := newTopicSubscriber(
+		sarama, "^orders-.*$", opts, make(chan kafka.Message, 1),
+		func(topic string) DLQ { return nil }, tally.NoopScope, zap.NewNop(), nil)
+	if err != nil {
+		t.Fatalf("newTopicSubscriber returned error: %v", err)
+	}
+	if err := sub.refresh(); err != nil {
+		t.Fatalf("refresh returned error: %v", err)
+	}
+
+	pc := newMockPartitionedConsumer("orders-us", 0, 0, 1)
+	sub.track(pc)
+	if len(sub.consumer["orders-us"]) != 1 {
+		t.Fatal("expected a tracked partitionConsumer for orders-us")
+	}
+
+	sarama.setTopics(nil)
+	if err := sub.refresh(); err != nil {
+		t.Fatalf("refresh returned error: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := sub.consumer["orders-us"]; ok {
+		t.Fatal("expected orders-us to be drained once it stops matching")
+	}
+	if !pc.isClosed() {
+		t.Fatal("expected underlying partition consumer to be closed on drain")
+	}
+}
+
+func TestTopicSubscriberWaitForReady(t *testing.T) {
+	sarama := newMockSaramaConsumer()
+	sarama.setTopics([]string{"orders-us"})
+
+	sub, err := newTopicSubscriber(
+		sarama, "^orders-.*$", DefaultOptions(), make(chan kafka.Message, 1),
+		func(topic string) DLQ { return nil }, tally.NoopScope, zap.NewNop(), nil)
+	if err != nil {
+		t.Fatalf("newTopicSubscriber returned error: %v", err)
+	}
+	if err := sub.refresh(); err != nil {
+		t.Fatalf("refresh returned error: %v", err)
+	}
+
+	sub.track(newMockPartitionedConsumer("orders-us", 0, 0, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sub.WaitForReady(ctx); err != nil {
+		t.Fatalf("WaitForReady returned error: %v", err)
+	}
+}
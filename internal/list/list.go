@@ -0,0 +1,118 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package list implements a capacity-bounded doubly linked list, used by
+// the consumer package to track in-flight, unacknowledged offsets.
+package list
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrCapacity is returned by PushBack when the list is already at capacity
+	ErrCapacity = errors.New("list: capacity exceeded")
+)
+
+type (
+	// Node is an element of a List, returned by PushBack and accepted by Remove.
+	Node struct {
+		Value      interface{}
+		prev, next *Node
+		list       *List
+	}
+
+	// List is a FIFO doubly linked list bounded to a fixed capacity.
+	List struct {
+		mu       sync.Mutex
+		capacity int
+		size     int
+		root     Node
+	}
+)
+
+// nodePool recycles Nodes across Lists. A PushBack/Remove pair under
+// sustained load would otherwise allocate and discard a Node per call,
+// which matters here because the consumer package calls PushBack once per
+// received message.
+var nodePool = sync.Pool{New: func() interface{} { return &Node{} }}
+
+// New returns an empty list that holds at most capacity elements.
+func New(capacity int) *List {
+	l := &List{capacity: capacity}
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	return l
+}
+
+// PushBack appends v to the back of the list, returning ErrCapacity if the
+// list is already full.
+func (l *List) PushBack(v interface{}) (*Node, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size >= l.capacity {
+		return nil, ErrCapacity
+	}
+	n := nodePool.Get().(*Node)
+	n.Value = v
+	n.list = l
+	last := l.root.prev
+	last.next = n
+	n.prev = last
+	n.next = &l.root
+	l.root.prev = n
+	l.size++
+	return n, nil
+}
+
+// Front returns the oldest element in the list, or nil if the list is empty.
+func (l *List) Front() *Node {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.size == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Remove detaches n from the list it belongs to and returns its value. It
+// is a no-op if n has already been removed.
+func (l *List) Remove(n *Node) interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n.list != l {
+		return nil
+	}
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	v := n.Value
+	n.Value, n.next, n.prev, n.list = nil, nil, nil, nil
+	l.size--
+	nodePool.Put(n)
+	return v
+}
+
+// Len returns the number of elements currently in the list.
+func (l *List) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
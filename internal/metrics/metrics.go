@@ -0,0 +1,59 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package metrics defines the metric names emitted by the consumer and
+// producer packages.
+package metrics
+
+const (
+	// KafkaPartitionStarted is emitted once when a partitionConsumer starts
+	KafkaPartitionStarted = "kafka.partition.started"
+	// KafkaPartitionStopped is emitted once when a partitionConsumer stops
+	KafkaPartitionStopped = "kafka.partition.stopped"
+	// KafkaPartitionLag is the time lag between now and the message timestamp
+	KafkaPartitionLag = "kafka.partition.lag"
+	// KafkaPartitionReadOffset is the offset of the last message read
+	KafkaPartitionReadOffset = "kafka.partition.read-offset"
+	// KafkaPartitionCommitOffset is the offset of the last message committed
+	KafkaPartitionCommitOffset = "kafka.partition.commit-offset"
+	// KafkaPartitionBacklog is the number of messages between the partition's high-water mark and the commit offset
+	KafkaPartitionBacklog = "kafka.partition.backlog"
+	// KafkaPartitionMessagesIn is incremented for every message read off a partition
+	KafkaPartitionMessagesIn = "kafka.partition.messages-in"
+	// KafkaPartitionAckMgrListFull is incremented when the ackManager runs out of capacity
+	KafkaPartitionAckMgrListFull = "kafka.partition.ackmgr-list-full"
+
+	// KafkaRebalanceCopartitionMismatch is incremented when a Copartitioned
+	// rebalance strategy detects subscribed topics with differing partition counts
+	KafkaRebalanceCopartitionMismatch = "kafka.rebalance.copartition-mismatch"
+
+	// KafkaPartitionReconnect is incremented on every attempt to reconnect
+	// a partition whose underlying cluster.PartitionConsumer closed unexpectedly
+	KafkaPartitionReconnect = "kafka.partition.reconnect"
+
+	// KafkaDLQBatchFlushed counts messages successfully flushed by a batchDLQ
+	KafkaDLQBatchFlushed = "kafka.dlq.batch-flushed"
+	// KafkaDLQBatchPartialFailure is incremented when a batchDLQ flush
+	// partially fails and some messages must be retried
+	KafkaDLQBatchPartialFailure = "kafka.dlq.batch-partial-failure"
+	// KafkaDLQMessageDropped is incremented when a message exceeds
+	// DLQMaxRetries and is dropped by a batchDLQ
+	KafkaDLQMessageDropped = "kafka.dlq.message-dropped"
+)
@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package util
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// RunLifecycle guards the start/stop transitions of a long running
+	// component so that Start/Stop are idempotent and safe to call
+	// concurrently.
+	RunLifecycle struct {
+		name    string
+		logger  *zap.Logger
+		started int32
+		stopped int32
+	}
+)
+
+// NewRunLifecycle returns a lifecycle helper for the component named name.
+func NewRunLifecycle(name string, logger *zap.Logger) *RunLifecycle {
+	return &RunLifecycle{name: name, logger: logger}
+}
+
+// Start invokes fn on the first call to Start. If fn returns an error the
+// lifecycle is reset to not-started, so a later call to Start invokes fn
+// again; once fn has succeeded, further calls do nothing.
+func (l *RunLifecycle) Start(fn func() error) error {
+	if !atomic.CompareAndSwapInt32(&l.started, 0, 1) {
+		return nil
+	}
+	if err := fn(); err != nil {
+		atomic.StoreInt32(&l.started, 0)
+		return err
+	}
+	l.logger.Info("started", zap.String("component", l.name))
+	return nil
+}
+
+// Stop invokes fn at most once, on the first call to Stop.
+func (l *RunLifecycle) Stop(fn func()) {
+	if !atomic.CompareAndSwapInt32(&l.stopped, 0, 1) {
+		return
+	}
+	fn()
+	l.logger.Info("stopped", zap.String("component", l.name))
+}
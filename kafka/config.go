@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kafka
+
+// ErrInvalidTopicSubscription is returned by ConsumerConfig.Validate when
+// TopicList and TopicPattern are not exactly one set, as documented on
+// ConsumerConfig.
+type ErrInvalidTopicSubscription struct {
+	HasTopicList    bool
+	HasTopicPattern bool
+}
+
+func (e *ErrInvalidTopicSubscription) Error() string {
+	if e.HasTopicList && e.HasTopicPattern {
+		return "kafka: ConsumerConfig must not set both TopicList and TopicPattern"
+	}
+	return "kafka: ConsumerConfig must set one of TopicList or TopicPattern"
+}
+
+// ConsumerConfig describes what a consumer should subscribe to and how.
+type ConsumerConfig struct {
+	// Cluster is the name of the kafka cluster to consume from, as
+	// registered with the cluster name resolver.
+	Cluster string
+
+	// GroupName is the consumer group this consumer will join.
+	GroupName string
+
+	// TopicList is the static list of topics to subscribe to. Mutually
+	// exclusive with TopicPattern - exactly one of the two must be set.
+	TopicList []string
+
+	// TopicPattern is a regular expression (RE2 syntax, as accepted by
+	// package regexp) matched against the full set of topics available
+	// on the cluster. Topics that match are subscribed to automatically
+	// and the subscription is kept current by periodically refreshing
+	// cluster metadata, so topics created after the consumer starts are
+	// picked up without a restart. Mutually exclusive with TopicList.
+	TopicPattern string
+}
+
+// isPatternSubscription reports whether this config subscribes via
+// TopicPattern rather than a static TopicList.
+func (c *ConsumerConfig) isPatternSubscription() bool {
+	return len(c.TopicPattern) > 0
+}
+
+// Validate enforces that exactly one of TopicList or TopicPattern is set,
+// per the contract documented on those fields. Callers building a consumer
+// from a ConsumerConfig should call this before doing anything else with it.
+func (c *ConsumerConfig) Validate() error {
+	hasTopicList := len(c.TopicList) > 0
+	hasTopicPattern := c.isPatternSubscription()
+	if hasTopicList == hasTopicPattern {
+		return &ErrInvalidTopicSubscription{HasTopicList: hasTopicList, HasTopicPattern: hasTopicPattern}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kafka
+
+import "testing"
+
+func TestConsumerConfigValidateAcceptsTopicListOnly(t *testing.T) {
+	c := &ConsumerConfig{TopicList: []string{"orders"}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected no error for TopicList alone, got: %v", err)
+	}
+}
+
+func TestConsumerConfigValidateAcceptsTopicPatternOnly(t *testing.T) {
+	c := &ConsumerConfig{TopicPattern: "^orders-.*$"}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected no error for TopicPattern alone, got: %v", err)
+	}
+}
+
+func TestConsumerConfigValidateRejectsNeitherSet(t *testing.T) {
+	c := &ConsumerConfig{}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("expected an error when neither TopicList nor TopicPattern is set")
+	}
+	if _, ok := err.(*ErrInvalidTopicSubscription); !ok {
+		t.Fatalf("expected *ErrInvalidTopicSubscription, got %T", err)
+	}
+}
+
+func TestConsumerConfigValidateRejectsBothSet(t *testing.T) {
+	c := &ConsumerConfig{TopicList: []string{"orders"}, TopicPattern: "^orders-.*$"}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("expected an error when both TopicList and TopicPattern are set")
+	}
+	if _, ok := err.(*ErrInvalidTopicSubscription); !ok {
+		t.Fatalf("expected *ErrInvalidTopicSubscription, got %T", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kafka
+
+import "context"
+
+type (
+	// PartitionState describes the lifecycle state of a single partition's consumer.
+	PartitionState int
+
+	// PartitionEvent is published whenever a partition consumer's state
+	// changes, most notably when it starts reconnecting after losing its
+	// connection to the broker.
+	PartitionEvent struct {
+		Topic     string
+		Partition int32
+		State     PartitionState
+	}
+
+	// Consumer is the top-level handle applications use to consume from kafka.
+	Consumer interface {
+		// Start starts consuming messages
+		Start() error
+		// Stop stops the consumer
+		Stop()
+		// Closed returns a channel that is closed once the consumer has fully stopped
+		Closed() <-chan struct{}
+		// Messages returns the channel of consumed messages
+		Messages() <-chan Message
+		// Errors returns the channel of consumer errors
+		Errors() <-chan error
+		// PartitionEvents reports partition-level lifecycle transitions so
+		// applications can, for example, gate readiness probes on every
+		// assigned partition being PartitionStateRunning.
+		PartitionEvents() <-chan PartitionEvent
+		// WaitForReady blocks until every partition currently assigned to
+		// this consumer has verified its offsets against the broker and
+		// begun delivering messages, or ctx is done. Applications can use
+		// this to gate traffic (e.g. a readiness probe) on the consumer
+		// actually being caught up enough to not silently skip messages.
+		WaitForReady(ctx context.Context) error
+	}
+)
+
+const (
+	// PartitionStateRunning means the partition is consuming normally
+	PartitionStateRunning PartitionState = iota
+	// PartitionStateReconnecting means the partition lost its connection to
+	// the broker and is retrying with backoff
+	PartitionStateReconnecting
+	// PartitionStateStopped means the partition consumer has stopped
+	PartitionStateStopped
+)
+
+func (s PartitionState) String() string {
+	switch s {
+	case PartitionStateRunning:
+		return "running"
+	case PartitionStateReconnecting:
+		return "reconnecting"
+	case PartitionStateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kafka
+
+// Message is a message read off of a kafka topic partition. Implementations
+// may be pooled and recycled once Ack or Nack returns - an application must
+// not use a Message, or any slice returned by Key/Value, after calling
+// either. With zero-copy delivery enabled, Key/Value may additionally alias
+// a buffer owned by the underlying kafka client library, so this
+// restriction is load-bearing rather than just a hint.
+type Message interface {
+	// Key is the (possibly nil) key of the message
+	Key() []byte
+	// Value is the message payload
+	Value() []byte
+	// Topic is the topic this message was read from
+	Topic() string
+	// Partition is the partition this message was read from
+	Partition() int32
+	// Offset is the offset of this message within its partition
+	Offset() int64
+	// Ack marks the message as successfully processed
+	Ack()
+	// Nack marks the message as failed; the implementation is expected
+	// to route it to a DLQ (if one is configured) rather than redeliver it
+	Nack()
+}